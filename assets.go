@@ -0,0 +1,90 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+)
+
+// Asset represents a Github release asset.
+type Asset struct {
+	Id                 int    `json:"id"`
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// listAssetsAt returns every asset currently attached to the release with
+// the given id, following pagination until a short page is returned.
+func listAssetsAt(apiURL string, releaseID int) ([]Asset, error) {
+	var assets []Asset
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/releases/%d/assets?per_page=100&page=%d", apiURL, releaseID, page)
+		data, err := doRequest("GET", endpoint, "application/json", nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		var pageAssets []Asset
+		if err := json.Unmarshal(data, &pageAssets); err != nil {
+			return nil, err
+		}
+		assets = append(assets, pageAssets...)
+
+		if len(pageAssets) < 100 {
+			break
+		}
+	}
+	return assets, nil
+}
+
+// deleteAssetAt removes an existing release asset so it can be re-uploaded.
+func deleteAssetAt(apiURL string, assetID int) error {
+	endpoint := fmt.Sprintf("%s/releases/assets/%d", apiURL, assetID)
+	_, err := doRequest("DELETE", endpoint, "application/json", nil, 0)
+	return err
+}
+
+// resolveCollisions applies the -file-exists policy to filepaths, given the
+// assets already attached to the release. It returns the filepaths that
+// should still be uploaded. Collision checks and any resulting deletes are
+// serialized, since they happen before the concurrent upload loop starts.
+func resolveCollisions(backend ReleaseBackend, release Release, filepaths []string) []string {
+	existing, err := backend.ListAssets(release.Id)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	existingByName := map[string]int{}
+	for _, asset := range existing {
+		existingByName[asset.Name] = asset.Id
+	}
+
+	var toUpload []string
+	for _, path := range filepaths {
+		filename := filepath.Base(path)
+		assetID, collides := existingByName[filename]
+		if !collides {
+			toUpload = append(toUpload, path)
+			continue
+		}
+
+		switch fileExistsFlag {
+		case "skip":
+			log.Printf("Asset %s already exists on release %s, skipping\n", filename, release.TagName)
+		case "fail":
+			log.Fatalf("Error: Asset %s already exists on release %s\n", filename, release.TagName)
+		default: // overwrite
+			log.Printf("Asset %s already exists on release %s, deleting before re-upload\n", filename, release.TagName)
+			if err := backend.DeleteAsset(assetID); err != nil {
+				log.Fatalln(err)
+			}
+			toUpload = append(toUpload, path)
+		}
+	}
+	return toUpload
+}
@@ -0,0 +1,218 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ReleaseBackend abstracts the REST semantics of the Git hosting service a
+// release is published to, so publishRelease, deleteDraftReleases and
+// uploadFile don't have to hard-code Github's API shape.
+type ReleaseBackend interface {
+	// CreateOrGetRelease creates release, or fetches the existing release
+	// with the same tag if one is already there, and returns it populated
+	// with its id and upload URL.
+	CreateOrGetRelease(release Release) (Release, error)
+	// ListDrafts returns every draft release in the repository.
+	ListDrafts() ([]Release, error)
+	DeleteRelease(releaseID int) error
+	ListAssets(releaseID int) ([]Asset, error)
+	DeleteAsset(assetID int) error
+	UploadAsset(uploadURL, path string) error
+}
+
+// newBackend selects a ReleaseBackend according to -backend, falling back to
+// auto-detecting Gitea or Github Enterprise from the GITEA_API / GITHUB_API /
+// -api-url settings when -backend is left unset.
+func newBackend() (ReleaseBackend, error) {
+	name := backendFlag
+	if name == "" {
+		switch {
+		case os.Getenv("GITEA_API") != "":
+			name = "gitea"
+		case apiURLFlag != "" || os.Getenv("GITHUB_API") != "":
+			name = "ghe"
+		default:
+			name = "github"
+		}
+	}
+
+	switch name {
+	case "github":
+		return &githubBackend{apiURL: githubAPIEndpoint}, nil
+	case "ghe":
+		return &githubBackend{apiURL: githubAPIEndpoint, uploadHost: uploadURLFlag}, nil
+	case "gitea":
+		return &giteaBackend{apiURL: githubAPIEndpoint}, nil
+	default:
+		return nil, fmt.Errorf("invalid -backend: %s", name)
+	}
+}
+
+// githubBackend implements ReleaseBackend for both github.com and Github
+// Enterprise. The two only differ in whether the upload host needs to be
+// substituted: github.com's "upload_url" in the release response already
+// points at uploads.github.com, while GHE needs uploadHost to replace the
+// scheme and host the API returned.
+type githubBackend struct {
+	apiURL     string
+	uploadHost string
+}
+
+func (b *githubBackend) CreateOrGetRelease(release Release) (Release, error) {
+	result, err := createOrGetReleaseAt(b.apiURL, release)
+	if err != nil {
+		return Release{}, err
+	}
+
+	if b.uploadHost != "" {
+		result.UploadURL = rewriteHost(result.UploadURL, b.uploadHost)
+	}
+	return result, nil
+}
+
+func (b *githubBackend) ListDrafts() ([]Release, error) {
+	return listDraftsAt(b.apiURL)
+}
+
+func (b *githubBackend) DeleteRelease(releaseID int) error {
+	endpoint := fmt.Sprintf("%s/releases/%d", b.apiURL, releaseID)
+	_, err := doRequest("DELETE", endpoint, "application/json", nil, 0)
+	return err
+}
+
+func (b *githubBackend) ListAssets(releaseID int) ([]Asset, error) {
+	return listAssetsAt(b.apiURL, releaseID)
+}
+
+func (b *githubBackend) DeleteAsset(assetID int) error {
+	return deleteAssetAt(b.apiURL, assetID)
+}
+
+func (b *githubBackend) UploadAsset(uploadURL, path string) error {
+	return uploadFile(uploadURL, path)
+}
+
+// giteaBackend implements ReleaseBackend for Gitea. Gitea's release and
+// asset listing endpoints are Github-compatible, but it reuses its API host
+// for uploads rather than returning a separate "upload_url", and its asset
+// upload endpoint takes the release id directly instead of a pre-built
+// templated URL.
+type giteaBackend struct {
+	apiURL string
+}
+
+func (b *giteaBackend) CreateOrGetRelease(release Release) (Release, error) {
+	result, err := createOrGetReleaseAt(b.apiURL, release)
+	if err != nil {
+		return Release{}, err
+	}
+
+	// Gitea doesn't return a templated "upload_url", so build the
+	// "POST /releases/{id}/assets?name=..." endpoint ourselves.
+	result.UploadURL = fmt.Sprintf("%s/releases/%d/assets", b.apiURL, result.Id)
+	return result, nil
+}
+
+func (b *giteaBackend) ListDrafts() ([]Release, error) {
+	return listDraftsAt(b.apiURL)
+}
+
+func (b *giteaBackend) DeleteRelease(releaseID int) error {
+	endpoint := fmt.Sprintf("%s/releases/%d", b.apiURL, releaseID)
+	_, err := doRequest("DELETE", endpoint, "application/json", nil, 0)
+	return err
+}
+
+func (b *giteaBackend) ListAssets(releaseID int) ([]Asset, error) {
+	return listAssetsAt(b.apiURL, releaseID)
+}
+
+func (b *giteaBackend) DeleteAsset(assetID int) error {
+	return deleteAssetAt(b.apiURL, assetID)
+}
+
+func (b *giteaBackend) UploadAsset(uploadURL, path string) error {
+	return uploadFile(uploadURL, path)
+}
+
+// createOrGetReleaseAt creates release against the Github-compatible API at
+// apiURL, falling back to fetching the existing release with the same tag
+// if one is already there. Both githubBackend and giteaBackend share this
+// logic, since their release-creation semantics are identical; only the
+// resulting UploadURL needs backend-specific handling.
+func createOrGetReleaseAt(apiURL string, release Release) (Release, error) {
+	releaseData, err := json.Marshal(release)
+	if err != nil {
+		return Release{}, err
+	}
+
+	endpoint := fmt.Sprintf("%s/releases", apiURL)
+	data, err := doRequest("POST", endpoint, "application/json", bytes.NewBuffer(releaseData), int64(len(releaseData)))
+	if err != nil && data != nil {
+		log.Println(err)
+		log.Println("Trying again assuming release already exists.")
+		endpoint = fmt.Sprintf("%s/releases/tags/%s", apiURL, release.TagName)
+		data, err = doRequest("GET", endpoint, "application/json", nil, 0)
+	}
+	if err != nil {
+		return Release{}, err
+	}
+
+	var result Release
+	if err := json.Unmarshal(data, &result); err != nil {
+		return Release{}, err
+	}
+	return result, nil
+}
+
+// listDraftsAt returns every draft release in the repository, following
+// pagination until a short page is returned. Shared by githubBackend and
+// giteaBackend, whose release-listing endpoints are identical.
+func listDraftsAt(apiURL string) ([]Release, error) {
+	var drafts []Release
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/releases?per_page=100&page=%d", apiURL, page)
+		data, err := doRequest("GET", endpoint, "application/json", nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		var releases []Release
+		if err := json.Unmarshal(data, &releases); err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			if release.Draft {
+				drafts = append(drafts, release)
+			}
+		}
+
+		if len(releases) < 100 {
+			break
+		}
+	}
+	return drafts, nil
+}
+
+// rewriteHost replaces the scheme and host of a templated upload URL
+// (e.g. "https://api.ghe.example.com/repos/o/r/releases/1/assets{?name}")
+// with uploadHost, keeping the rest of the path intact. This is how Github
+// Enterprise's separate upload host gets applied to a URL the API returned
+// relative to its own, different, host.
+func rewriteHost(uploadURL, uploadHost string) string {
+	parsed, err := url.Parse(uploadURL)
+	if err != nil {
+		return uploadURL
+	}
+	return strings.TrimRight(uploadHost, "/") + parsed.RequestURI()
+}
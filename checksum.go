@@ -0,0 +1,190 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var hashConstructors = map[string]func() hash.Hash{
+	"md5":     md5.New,
+	"sha1":    sha1.New,
+	"sha256":  sha256.New,
+	"sha512":  sha512.New,
+	"adler32": func() hash.Hash { return adler32.New() },
+	"crc32":   func() hash.Hash { return crc32.NewIEEE() },
+}
+
+// checksums holds the hex digest computed for every uploaded asset, keyed by
+// algorithm and then by asset filename. It is populated concurrently by
+// uploadFile, guarded by checksumsMu.
+var (
+	checksums   = map[string]map[string]string{}
+	checksumsMu sync.Mutex
+)
+
+// checksumAlgorithms parses the comma-separated -checksum flag, validating
+// each algorithm name against hashConstructors.
+func checksumAlgorithms() []string {
+	if checksumFlag == "" {
+		return nil
+	}
+
+	var algos []string
+	for _, a := range strings.Split(checksumFlag, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		if _, ok := hashConstructors[a]; !ok {
+			log.Fatalf("Error: Unsupported checksum algorithm: %s\n", a)
+		}
+		algos = append(algos, a)
+	}
+	return algos
+}
+
+// checksumWriter feeds every requested hash algorithm as a file is streamed
+// to the upload request, so large assets only need to be read once.
+type checksumWriter struct {
+	hashes map[string]hash.Hash
+}
+
+func newChecksumWriter(algos []string) *checksumWriter {
+	if len(algos) == 0 {
+		return nil
+	}
+
+	cw := &checksumWriter{hashes: map[string]hash.Hash{}}
+	for _, a := range algos {
+		cw.hashes[a] = hashConstructors[a]()
+	}
+	return cw
+}
+
+// wrap returns an io.Reader that updates every configured hash as it is
+// read from, without buffering the file in memory.
+func (cw *checksumWriter) wrap(r io.Reader) io.Reader {
+	if cw == nil {
+		return r
+	}
+
+	writers := make([]io.Writer, 0, len(cw.hashes))
+	for _, h := range cw.hashes {
+		writers = append(writers, h)
+	}
+	return io.TeeReader(r, io.MultiWriter(writers...))
+}
+
+// record saves the final digests under filename once the upload has
+// finished reading the wrapped file.
+func (cw *checksumWriter) record(filename string) {
+	if cw == nil {
+		return
+	}
+
+	checksumsMu.Lock()
+	defer checksumsMu.Unlock()
+	for algo, h := range cw.hashes {
+		if checksums[algo] == nil {
+			checksums[algo] = map[string]string{}
+		}
+		checksums[algo][filename] = fmt.Sprintf("%x", h.Sum(nil))
+	}
+}
+
+// uploadChecksums builds the checksum file(s) requested via -checksum and
+// -checksum-format and uploads them as additional release assets, once every
+// other asset has finished uploading. The generated paths are run through
+// the same -file-exists collision handling as the original assets, since a
+// re-run of create against a tag that already has a checksum file from a
+// prior run would otherwise always hit a 422 on it.
+func uploadChecksums(backend ReleaseBackend, release Release, uploadURL string, filepaths []string, tag string) error {
+	algos := checksumAlgorithms()
+	if len(algos) == 0 {
+		return nil
+	}
+
+	var paths []string
+	switch checksumFormatFlag {
+	case "sidecar":
+		paths = writeSidecarChecksums(algos, filepaths)
+	case "combined":
+		paths = writeCombinedChecksums(algos, filepaths, tag)
+	default:
+		log.Fatalf("Error: Invalid -checksum-format: %s\n", checksumFormatFlag)
+	}
+
+	toUpload := resolveCollisions(backend, release, paths)
+
+	var errs []error
+	for _, path := range toUpload {
+		if err := uploadFile(uploadURL, path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, path := range paths {
+		os.Remove(path)
+	}
+	return errors.Join(errs...)
+}
+
+// writeCombinedChecksums writes one file per algorithm named
+// "<tag>_<algo>sums.txt", each containing a coreutils-style
+// "<hex>  <filename>" line per asset.
+func writeCombinedChecksums(algos, filepaths []string, tag string) []string {
+	var paths []string
+	for _, algo := range algos {
+		var sb strings.Builder
+		for _, path := range filepaths {
+			filename := filepath.Base(path)
+			sb.WriteString(fmt.Sprintf("%s  %s\n", checksums[algo][filename], filename))
+		}
+
+		name := fmt.Sprintf("%s_%ssums.txt", tag, algo)
+		path := filepath.Join(os.TempDir(), name)
+		if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+			log.Printf("Error: Failed to write checksum file %s: %s\n", name, err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// writeSidecarChecksums writes one "<asset>.<algo>" file per asset per
+// algorithm, each containing a single coreutils-style line.
+func writeSidecarChecksums(algos, filepaths []string) []string {
+	var paths []string
+	for _, path := range filepaths {
+		filename := filepath.Base(path)
+		for _, algo := range algos {
+			line := fmt.Sprintf("%s  %s\n", checksums[algo][filename], filename)
+
+			name := fmt.Sprintf("%s.%s", filename, algo)
+			sidecarPath := filepath.Join(os.TempDir(), name)
+			if err := os.WriteFile(sidecarPath, []byte(line), 0644); err != nil {
+				log.Printf("Error: Failed to write checksum file %s: %s\n", name, err)
+				continue
+			}
+			paths = append(paths, sidecarPath)
+		}
+	}
+	return paths
+}
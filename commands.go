@@ -0,0 +1,88 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Command is a single github-release subcommand. Each subcommand owns its
+// own flag.FlagSet internally, so flags are parsed per-command instead of
+// globally up front.
+type Command struct {
+	Name  string
+	Usage string
+	Run   func(args []string) error
+}
+
+var commands = []*Command{
+	createCommand,
+	listCommand,
+	showCommand,
+	downloadCommand,
+	editCommand,
+	deleteCommand,
+}
+
+func lookupCommand(name string) *Command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// dispatch picks which subcommand should handle args. For backwards
+// compatibility with the original positional-only CLI, anything that
+// doesn't name a known subcommand (a flag, or the "<user/repo>" argument)
+// is routed to "create" with the full argument list.
+func dispatch(args []string) (*Command, []string) {
+	if len(args) > 0 {
+		if cmd := lookupCommand(args[0]); cmd != nil {
+			return cmd, args[1:]
+		}
+	}
+	return createCommand, args
+}
+
+// setGithubTarget parses a "<user/repo>" argument and points githubAPIEndpoint
+// at it. It must be called once per command invocation before any Github API
+// calls are made.
+func setGithubTarget(userRepo string) error {
+	parts := strings.Split(userRepo, "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid format used for username and repository: %s", userRepo)
+	}
+	githubUser = parts[0]
+	githubRepo = parts[1]
+	githubAPIEndpoint = fmt.Sprintf("%s/repos/%s/%s", githubAPIEndpoint, githubUser, githubRepo)
+	return nil
+}
+
+func requireToken() error {
+	if githubToken == "" {
+		return fmt.Errorf(`GITHUB_TOKEN environment variable is not set.
+Please refer to https://help.github.com/articles/creating-an-access-token-for-command-line-use/ for more help`)
+	}
+	return nil
+}
+
+// getReleaseByTag fetches a single release, including its asset list.
+func getReleaseByTag(tag string) (*Release, error) {
+	endpoint := fmt.Sprintf("%s/releases/tags/%s", githubAPIEndpoint, tag)
+	data, err := doRequest("GET", endpoint, "application/json", nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	release := &Release{}
+	if err := json.Unmarshal(data, release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
@@ -0,0 +1,124 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var createCommand = &Command{
+	Name:  "create",
+	Usage: "create <user/repo> <tag> <branch> [flags]",
+	Run:   runCreate,
+}
+
+func runCreate(args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	fs.BoolVar(&prereleaseFlag, "prerelease", false, "Identify the release as a prerelease")
+	fs.BoolVar(&draftFlag, "draft", false, "Save as draft, don't publish")
+	fs.BoolVar(&recreateDraftFlag, "recreateDraft", false, "Deletes the previous release drafts matching the tag of the release, if they exist")
+	fs.BoolVar(&latest, "latest", true, "Mark this release as latest")
+	fs.StringVar(&descFile, "description", "", "Path to a file containing the release description")
+	fs.StringVar(&assets, "assets", "", "Glob pattern describing the list of files to include in the release. "+
+		"Multiple glob patterns can be supplied, delimited by a whitespace.")
+	fs.StringVar(&checksumFlag, "checksum", "", "Comma-separated list of hash algorithms to compute for every asset "+
+		"and publish as additional release assets. Supported: md5, sha1, sha256, sha512, adler32, crc32.")
+	fs.StringVar(&checksumFormatFlag, "checksum-format", "combined", `Either "combined", which publishes one `+
+		`"<tag>_<algo>sums.txt" file per algorithm listing every asset, or "sidecar", which publishes one `+
+		`"<asset>.<algo>" file per asset per algorithm.`)
+	fs.StringVar(&fileExistsFlag, "file-exists", "overwrite", `What to do when an asset with the same name already `+
+		`exists on the release: "overwrite" deletes and re-uploads it, "skip" leaves it alone, "fail" aborts the run.`)
+	fs.IntVar(&uploadRetriesFlag, "upload-retries", 3, "Number of times to retry an asset upload after a "+
+		"retriable (5xx) error, with exponential backoff.")
+	fs.BoolVar(&generateNotesFlag, "generate-notes", false, "Generate the release description automatically "+
+		"instead of reading -description")
+	fs.StringVar(&notesFromFlag, "notes-from", "github", `Where to generate notes from when -generate-notes is `+
+		`set: "github" or "gitlog"`)
+	fs.StringVar(&previousTagFlag, "previous-tag", "", "Overrides the previous tag used as the starting point "+
+		"when generating notes")
+	fs.StringVar(&backendFlag, "backend", "", `Which hosting service to publish to: "github", "ghe" or "gitea". `+
+		"Auto-detected from GITEA_API/-api-url when left unset.")
+	fs.StringVar(&apiURLFlag, "api-url", "", "Github Enterprise or Gitea API endpoint, e.g. https://git.example.com/api/v1")
+	fs.StringVar(&uploadURLFlag, "upload-url", "", "Github Enterprise upload host, when it differs from -api-url")
+	fs.Parse(args)
+
+	if fs.NArg() != 3 {
+		return fmt.Errorf("invalid number of arguments (got %d, expected 3: <user/repo> <tag> <branch>)", fs.NArg())
+	}
+
+	if descFile == "" && !generateNotesFlag {
+		return fmt.Errorf("no -description file supplied")
+	}
+
+	if err := requireToken(); err != nil {
+		return err
+	}
+
+	if apiURLFlag == "" {
+		apiURLFlag = os.Getenv("GITEA_API")
+	}
+	if apiURLFlag != "" {
+		githubAPIEndpoint = apiURLFlag
+	}
+
+	if err := setGithubTarget(fs.Arg(0)); err != nil {
+		return err
+	}
+
+	tag := fs.Arg(1)
+	branch := fs.Arg(2)
+
+	var desc string
+	if generateNotesFlag {
+		notes, err := generateNotes(tag, branch, previousTagFlag)
+		if err != nil {
+			return fmt.Errorf("failed to generate release notes: %w", err)
+		}
+		desc = notes
+	} else {
+		data, err := os.ReadFile(descFile)
+		if err != nil {
+			return fmt.Errorf("failed to read description file '%s': %w", descFile, err)
+		}
+		desc = string(data)
+	}
+	fmt.Println("description:", desc)
+
+	var filepaths []string
+	for _, glob := range strings.Split(assets, " ") {
+		paths, err := filepath.Glob(glob)
+		if err != nil {
+			return fmt.Errorf("invalid glob pattern: %s", glob)
+		}
+		filepaths = append(filepaths, paths...)
+	}
+
+	if debug {
+		log.Println("Expanded glob pattern: ")
+		log.Printf("%v\n", filepaths)
+	}
+
+	release := Release{
+		TagName:    tag,
+		Name:       tag,
+		Prerelease: prereleaseFlag,
+		Draft:      draftFlag,
+		Branch:     branch,
+		Body:       desc,
+		MakeLatest: fmt.Sprintf("%v", latest),
+	}
+
+	if err := publishRelease(release, filepaths); err != nil {
+		return fmt.Errorf("one or more assets failed to upload: %w", err)
+	}
+
+	log.Println("Done")
+	return nil
+}
@@ -0,0 +1,55 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+)
+
+var deleteCommand = &Command{
+	Name:  "delete",
+	Usage: "delete <user/repo> <tag> [flags]",
+	Run:   runDelete,
+}
+
+func runDelete(args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	includeTag := fs.Bool("include-tag", false, "Also delete the underlying git tag/ref")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("invalid number of arguments (got %d, expected 2: <user/repo> <tag>)", fs.NArg())
+	}
+
+	if err := requireToken(); err != nil {
+		return err
+	}
+
+	if err := setGithubTarget(fs.Arg(0)); err != nil {
+		return err
+	}
+
+	release, err := getReleaseByTag(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/releases/%d", githubAPIEndpoint, release.Id)
+	if _, err := doRequest("DELETE", endpoint, "application/json", nil, 0); err != nil {
+		return err
+	}
+
+	if *includeTag {
+		endpoint := fmt.Sprintf("%s/git/refs/tags/%s", githubAPIEndpoint, fs.Arg(1))
+		if _, err := doRequest("DELETE", endpoint, "application/json", nil, 0); err != nil {
+			return err
+		}
+	}
+
+	log.Println("Done")
+	return nil
+}
@@ -0,0 +1,97 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+var downloadCommand = &Command{
+	Name:  "download",
+	Usage: "download <user/repo> <tag> [flags]",
+	Run:   runDownload,
+}
+
+func runDownload(args []string) error {
+	fs := flag.NewFlagSet("download", flag.ExitOnError)
+	pattern := fs.String("pattern", "", "Only download assets whose name matches this glob pattern")
+	output := fs.String("output", ".", "Directory to download assets into")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("invalid number of arguments (got %d, expected 2: <user/repo> <tag>)", fs.NArg())
+	}
+
+	if err := requireToken(); err != nil {
+		return err
+	}
+
+	if err := setGithubTarget(fs.Arg(0)); err != nil {
+		return err
+	}
+
+	release, err := getReleaseByTag(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		return err
+	}
+
+	for _, asset := range release.Assets {
+		if *pattern != "" {
+			matched, err := filepath.Match(*pattern, asset.Name)
+			if err != nil {
+				return fmt.Errorf("invalid -pattern: %w", err)
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		if err := downloadAsset(asset, *output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadAsset streams a single release asset straight from its
+// browser_download_url, bypassing the Github API host entirely.
+func downloadAsset(asset Asset, dir string) error {
+	log.Printf("Downloading %s...\n", asset.Name)
+
+	req, err := http.NewRequest("GET", asset.BrowserDownloadURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", asset.Name, resp.Status)
+	}
+
+	file, err := os.Create(filepath.Join(dir, asset.Name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, resp.Body)
+	return err
+}
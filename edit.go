@@ -0,0 +1,74 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+var editCommand = &Command{
+	Name:  "edit",
+	Usage: "edit <user/repo> <tag> [flags]",
+	Run:   runEdit,
+}
+
+func runEdit(args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ExitOnError)
+	draft := fs.Bool("draft", false, "Save as draft, don't publish")
+	prerelease := fs.Bool("prerelease", false, "Identify the release as a prerelease")
+	descFile := fs.String("description", "", "Path to a file containing the new release description")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("invalid number of arguments (got %d, expected 2: <user/repo> <tag>)", fs.NArg())
+	}
+
+	if err := requireToken(); err != nil {
+		return err
+	}
+
+	if err := setGithubTarget(fs.Arg(0)); err != nil {
+		return err
+	}
+
+	release, err := getReleaseByTag(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	// draft/prerelease default to false, so only apply them when the user
+	// actually passed the flag - otherwise an unrelated edit (e.g. just
+	// -description) would silently flip an existing draft/prerelease
+	// release back to published/non-prerelease.
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "draft":
+			release.Draft = *draft
+		case "prerelease":
+			release.Prerelease = *prerelease
+		}
+	})
+
+	if *descFile != "" {
+		desc, err := os.ReadFile(*descFile)
+		if err != nil {
+			return fmt.Errorf("failed to read description file '%s': %w", *descFile, err)
+		}
+		release.Body = string(desc)
+	}
+
+	releaseData, err := json.Marshal(release)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/releases/%d", githubAPIEndpoint, release.Id)
+	_, err = doRequest("PATCH", endpoint, "application/json", bytes.NewBuffer(releaseData), int64(len(releaseData)))
+	return err
+}
@@ -0,0 +1,80 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+var listCommand = &Command{
+	Name:  "list",
+	Usage: "list <user/repo> [flags]",
+	Run:   runList,
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	includeDrafts := fs.Bool("include-drafts", false, "Include draft releases in the listing")
+	includePrereleases := fs.Bool("include-prereleases", false, "Include prereleases in the listing")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("invalid number of arguments (got %d, expected 1: <user/repo>)", fs.NArg())
+	}
+
+	if err := requireToken(); err != nil {
+		return err
+	}
+
+	if err := setGithubTarget(fs.Arg(0)); err != nil {
+		return err
+	}
+
+	releases, err := listReleases()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TAG\tNAME\tCREATED\tASSETS")
+	for _, release := range releases {
+		if release.Draft && !*includeDrafts {
+			continue
+		}
+		if release.Prerelease && !*includePrereleases {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", release.TagName, release.Name, release.CreatedAt.Format("2006-01-02"), len(release.Assets))
+	}
+	return w.Flush()
+}
+
+// listReleases returns every release in the repository, following
+// pagination until a short page is returned.
+func listReleases() ([]Release, error) {
+	var releases []Release
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/releases?per_page=100&page=%d", githubAPIEndpoint, page)
+		data, err := doRequest("GET", endpoint, "application/json", nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		var pageReleases []Release
+		if err := json.Unmarshal(data, &pageReleases); err != nil {
+			return nil, err
+		}
+		releases = append(releases, pageReleases...)
+
+		if len(pageReleases) < 100 {
+			break
+		}
+	}
+	return releases, nil
+}
@@ -5,9 +5,7 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
-	"flag"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -15,10 +13,10 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 var (
@@ -33,25 +31,51 @@ var (
 
 // Release represents a Github Release.
 type Release struct {
-	Id         int    `json:"id,omitempty"`
-	UploadURL  string `json:"upload_url,omitempty"`
-	TagName    string `json:"tag_name"`
-	Branch     string `json:"target_commitish"`
-	Name       string `json:"name"`
-	Body       string `json:"body"`
-	Draft      bool   `json:"draft"`
-	Prerelease bool   `json:"prerelease"`
-	MakeLatest string `json:"make_latest"`
+	Id         int       `json:"id,omitempty"`
+	UploadURL  string    `json:"upload_url,omitempty"`
+	TagName    string    `json:"tag_name"`
+	Branch     string    `json:"target_commitish"`
+	Name       string    `json:"name"`
+	Body       string    `json:"body"`
+	Draft      bool      `json:"draft"`
+	Prerelease bool      `json:"prerelease"`
+	MakeLatest string    `json:"make_latest"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+	Assets     []Asset   `json:"assets,omitempty"`
 }
 
+// httpError represents a non-2xx response from the Github API, preserving
+// the status code so callers can decide whether it is worth retrying.
+type httpError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("Github returned an error:\n Code: %s. \n Body: %s", e.Status, e.Body)
+}
+
+// Flags shared across subcommands. Each subcommand owns its own
+// flag.FlagSet and binds the ones it understands, rather than parsing a
+// single global set up front.
 var (
-	verFlag           bool
-	prereleaseFlag    bool
-	draftFlag         bool
-	recreateDraftFlag bool
-	latest            bool
-	descFile          string
-	assets            string
+	prereleaseFlag     bool
+	draftFlag          bool
+	recreateDraftFlag  bool
+	latest             bool
+	descFile           string
+	assets             string
+	checksumFlag       string
+	checksumFormatFlag string
+	fileExistsFlag     string
+	uploadRetriesFlag  int
+	generateNotesFlag  bool
+	notesFromFlag      string
+	previousTagFlag    string
+	backendFlag        string
+	apiURLFlag         string
+	uploadURLFlag      string
 )
 
 func init() {
@@ -67,37 +91,27 @@ func init() {
 	if githubAPIEndpoint == "" {
 		githubAPIEndpoint = "https://api.github.com"
 	}
-
-	flag.BoolVar(&verFlag, "version", false, "-version")
-	flag.BoolVar(&prereleaseFlag, "prerelease", false, "-prerelease")
-	flag.BoolVar(&draftFlag, "draft", false, "-draft")
-	flag.BoolVar(&recreateDraftFlag, "recreateDraft", false, "-recreateDraft")
-	flag.BoolVar(&latest, "latest", true, "-latest")
-	flag.StringVar(&descFile, "description", "", "-description")
-	flag.StringVar(&assets, "assets", "", "-assets")
-	flag.Parse()
 }
 
 var usage = `Github command line release tool.
 
 Usage:
-	github-release <user/repo> <tag> <branch>
+	github-release <command> [arguments]
 
-Parameters:
-	<user/repo>: Github user and repository
-	<tag>: Used to created the release. It is also used as the release's name
-	<branch>: Reference from where to create the provided <tag>, if it does not exist
+Commands:
+	create <user/repo> <tag> <branch> [flags]: Creates (or updates) a release. This is
+		also the implicit command when none of the other command names are given, for
+		backwards compatibility with older invocations of this tool.
+	list <user/repo> [flags]: Lists releases.
+	show <user/repo> <tag>: Prints a release and its assets as JSON.
+	download <user/repo> <tag> [flags]: Downloads a release's assets.
+	edit <user/repo> <tag> [flags]: Edits an existing release.
+	delete <user/repo> <tag> [flags]: Deletes a release.
+
+Run "github-release <command> -h" to see the flags a given command understands.
 
 Options:
 	-version: Displays version
-	-prerelease: Identify the release as a prerelease
-	-draft: Save as draft, don't publish
-	-recreateDraft: Deletes the previous release drafts matching the tag of the release, if they exist
-	-latest: Mark this release as latest. Default true.
-	-description: Path to a file containing the release description.
-	-assets: Glob pattern describing the list of files to include in the release.
-	Multiple glob patterns can be supplied, delimited by a whitespace.
-	Make sure you enclose it in quotes to avoid the shell expanding the glob pattern.
 
 Environment variables:
   DEBUG: Allows you to run github-release in debugging mode. DO NOT do this if you are attempting to upload big files.
@@ -116,105 +130,27 @@ License: http://mozilla.org/MPL/2.0/
 `
 
 func main() {
-	if verFlag {
+	args := os.Args[1:]
+
+	if len(args) > 0 && (args[0] == "-version" || args[0] == "--version") {
 		log.Println(Version)
 		return
 	}
 
-	if flag.NArg() != 3 {
-		log.Printf("Error: Invalid number of arguments (got %d, expected 3)\n\n", flag.NArg())
-		log.Fatal(usage)
-	}
-
-	if descFile == "" {
-		log.Fatalf("Error: No -description file supplied.")
-	}
-
-	userRepo := strings.Split(flag.Arg(0), "/")
-	if len(userRepo) != 2 {
-		log.Printf("Error: Invalid format used for username and repository: %s\n\n", flag.Arg(0))
+	if len(args) == 0 {
 		log.Fatal(usage)
 	}
 
-	if githubToken == "" {
-		log.Fatal(`Error: GITHUB_TOKEN environment variable is not set.
-Please refer to https://help.github.com/articles/creating-an-access-token-for-command-line-use/ for more help`)
-	}
-
-	githubUser = userRepo[0]
-	githubRepo = userRepo[1]
-	githubAPIEndpoint = fmt.Sprintf("%s/repos/%s/%s", githubAPIEndpoint, githubUser, githubRepo)
-
-	if debug {
-		log.Println("Glob pattern received: ")
-		log.Println(flag.Arg(4))
-	}
-
-	var filepaths []string
-	for _, glob := range strings.Split(assets, " ") {
-		paths, err := filepath.Glob(glob)
-		if err != nil {
-			log.Fatalf("Error: Invalid glob pattern: %s\n", glob)
-		}
-		filepaths = append(filepaths, paths...)
-	}
-
-	if debug {
-		log.Println("Expanded glob pattern: ")
-		log.Printf("%v\n", filepaths)
-	}
-
-	tag := flag.Arg(1)
-	branch := flag.Arg(2)
-	desc, err := os.ReadFile(descFile)
-	if err != nil {
-		log.Fatalf("Error: Failed to read description file '%s': %s", descFile, err)
-	}
-	fmt.Println("description:", string(desc))
-
-	release := Release{
-		TagName:    tag,
-		Name:       tag,
-		Prerelease: prereleaseFlag,
-		Draft:      draftFlag,
-		Branch:     branch,
-		Body:       string(desc),
-		MakeLatest: fmt.Sprintf("%v", latest),
-	}
-	publishRelease(release, filepaths)
-	log.Println("Done")
-}
-
-func uploadFile(uploadURL, path string) {
-	file, err := os.Open(path)
-	if err != nil {
-		log.Printf("Error: %s\n", err.Error())
-		return
-	}
-	defer file.Close()
-
-	size, err := fileSize(file)
-	if err != nil {
-		log.Printf("Error: %s\n", err.Error())
-		return
-	}
-
-	filename := filepath.Base(file.Name())
-	log.Printf("Uploading %s...\n", filename)
-	body, err := doRequest("POST", uploadURL+"?name="+filename, "application/octet-stream", file, size)
-	if err != nil {
-		log.Printf("Error: %s\n", err.Error())
-	}
-
-	if debug {
-		log.Println("========= UPLOAD RESPONSE ===========")
-		log.Println(string(body[:]))
+	cmd, rest := dispatch(args)
+	if err := cmd.Run(rest); err != nil {
+		log.Printf("Error: %s\n", err)
+		os.Exit(1)
 	}
 }
 
 // CreateRelease creates a Github Release, attaching the given files as release assets
 // If a release already exist, up in Github, this function will attempt to attach the given files to it.
-func CreateRelease(tag, branch, desc string, filepaths []string) {
+func CreateRelease(tag, branch, desc string, filepaths []string) error {
 	release := Release{
 		TagName:    tag,
 		Name:       tag,
@@ -223,71 +159,42 @@ func CreateRelease(tag, branch, desc string, filepaths []string) {
 		Branch:     branch,
 		Body:       desc,
 	}
-	publishRelease(release, filepaths)
+	return publishRelease(release, filepaths)
 }
 
-func deleteDraftReleases(tag string) {
+func deleteDraftReleases(backend ReleaseBackend, tag string) {
 	log.Println("Deleting old draft releases, if they exists")
-	deleteDraftReleasesRec(tag, 1)
-}
 
-func deleteDraftReleasesRec(tag string, page int) {
-	endpoint := fmt.Sprintf("%s/releases?per_page=100&page=%d", githubAPIEndpoint, page)
-	data, err := doRequest("GET", endpoint, "application/json", nil, 0)
+	drafts, err := backend.ListDrafts()
 	if err != nil {
 		log.Println(err)
 		log.Println("Failed to get old release drafts to delete, creating new release")
 		return
 	}
-	releases := []Release{}
-	err = json.Unmarshal(data, &releases)
-	if err != nil {
-		log.Println(err)
-		log.Println("Failed to unmarshal old release drafts to delete, creating new release")
-		return
-	}
-	for _, release := range releases {
-		if release.Draft && release.TagName == tag {
-			log.Printf("Deleting release draft with tag %s and id %d\n", release.TagName, release.Id)
-			endpoint = fmt.Sprintf("%s/releases/%d", githubAPIEndpoint, release.Id)
-			_, err = doRequest("DELETE", endpoint, "application/json", nil, 0)
-			if err != nil {
-				log.Println(err)
-				log.Printf("Failed to delete old release draft with id %d\n", release.Id)
-			}
+
+	for _, release := range drafts {
+		if release.TagName != tag {
+			continue
+		}
+		log.Printf("Deleting release draft with tag %s and id %d\n", release.TagName, release.Id)
+		if err := backend.DeleteRelease(release.Id); err != nil {
+			log.Println(err)
+			log.Printf("Failed to delete old release draft with id %d\n", release.Id)
 		}
-	}
-	if len(releases) == 100 {
-		deleteDraftReleasesRec(tag, page+1)
 	}
 }
 
-func publishRelease(release Release, filepaths []string) {
-	endpoint := fmt.Sprintf("%s/releases", githubAPIEndpoint)
-	releaseData, err := json.Marshal(release)
+func publishRelease(release Release, filepaths []string) error {
+	backend, err := newBackend()
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
-	releaseBuffer := bytes.NewBuffer(releaseData)
 
 	if recreateDraftFlag {
-		deleteDraftReleases(release.TagName)
-	}
-
-	data, err := doRequest("POST", endpoint, "application/json", releaseBuffer, int64(releaseBuffer.Len()))
-
-	if err != nil && data != nil {
-		log.Println(err)
-		log.Println("Trying again assuming release already exists.")
-		endpoint = fmt.Sprintf("%s/releases/tags/%s", githubAPIEndpoint, release.TagName)
-		data, err = doRequest("GET", endpoint, "application/json", nil, 0)
-	}
-	if err != nil {
-		log.Fatalln(err)
+		deleteDraftReleases(backend, release.TagName)
 	}
 
-	// Gets the release Upload URL from the returned JSON data
-	err = json.Unmarshal(data, &release)
+	release, err = backend.CreateOrGetRelease(release)
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -296,15 +203,35 @@ func publishRelease(release Release, filepaths []string) {
 	// So we need to remove the {?name} part
 	uploadURL := strings.Split(release.UploadURL, "{")[0]
 
+	filepaths = resolveCollisions(backend, release, filepaths)
+
 	var wg sync.WaitGroup
+	errs := make(chan error, len(filepaths))
 	for i := range filepaths {
 		wg.Add(1)
-		func(index int) {
-			uploadFile(uploadURL, filepaths[index])
-			wg.Done()
+		go func(index int) {
+			defer wg.Done()
+			if err := backend.UploadAsset(uploadURL, filepaths[index]); err != nil {
+				errs <- err
+			}
 		}(i)
 	}
 	wg.Wait()
+	close(errs)
+
+	var uploadErrs []error
+	for err := range errs {
+		uploadErrs = append(uploadErrs, err)
+	}
+
+	if err := uploadChecksums(backend, release, uploadURL, filepaths, release.TagName); err != nil {
+		uploadErrs = append(uploadErrs, err)
+	}
+
+	if len(uploadErrs) > 0 {
+		return errors.Join(uploadErrs...)
+	}
+	return nil
 }
 
 func fileSize(file *os.File) (int64, error) {
@@ -359,7 +286,7 @@ func doRequest(method, url, contentType string, reqBody io.Reader, bodySize int6
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusCreated {
-		return respBody, fmt.Errorf("Github returned an error:\n Code: %s. \n Body: %s", resp.Status, respBody)
+		return respBody, &httpError{StatusCode: resp.StatusCode, Status: resp.Status, Body: respBody}
 	}
 
 	return respBody, nil
@@ -0,0 +1,147 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// conventionalCommitTypes lists the Conventional Commit prefixes we group
+// gitlog-sourced notes by, in the order they should appear in the output.
+var conventionalCommitTypes = []struct {
+	Prefix string
+	Title  string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"docs", "Documentation"},
+	{"chore", "Chores"},
+}
+
+var conventionalCommitRe = regexp.MustCompile(`^- (\w+)(\([^)]*\))?: `)
+
+// generateNotes produces a release description for tag according to
+// -notes-from, without requiring a precomputed -description file.
+func generateNotes(tag, branch, previousTag string) (string, error) {
+	switch notesFromFlag {
+	case "gitlog":
+		return generateNotesFromGitLog(tag, previousTag)
+	case "github":
+		return generateNotesFromGithub(tag, branch, previousTag)
+	default:
+		return "", fmt.Errorf("invalid -notes-from: %s", notesFromFlag)
+	}
+}
+
+// generateNotesFromGithub asks Github to compile notes the same way it does
+// for releases created through the web UI.
+func generateNotesFromGithub(tag, branch, previousTag string) (string, error) {
+	reqBody := map[string]string{
+		"tag_name":         tag,
+		"target_commitish": branch,
+	}
+	if previousTag != "" {
+		reqBody["previous_tag_name"] = previousTag
+	}
+
+	data, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := fmt.Sprintf("%s/releases/generate-notes", githubAPIEndpoint)
+	resp, err := doRequest("POST", endpoint, "application/json", bytes.NewBuffer(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", err
+	}
+	return result.Body, nil
+}
+
+// generateNotesFromGitLog builds Markdown release notes from the commits
+// between previousTag and tag, grouping them by Conventional Commit type.
+// When previousTag is empty it is auto-discovered via "git describe".
+func generateNotesFromGitLog(tag, previousTag string) (string, error) {
+	if previousTag == "" {
+		out, err := exec.Command("git", "describe", "--tags", "--abbrev=0", tag+"^").Output()
+		if err != nil {
+			log.Println("Warning: Failed to auto-discover the previous tag, using the full log history:", err)
+		} else {
+			previousTag = strings.TrimSpace(string(out))
+		}
+	}
+
+	revRange := tag
+	if previousTag != "" {
+		revRange = previousTag + ".." + tag
+	}
+
+	out, err := exec.Command("git", "log", revRange, `--pretty=format:- %s (%h)`).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run git log: %w", err)
+	}
+
+	sections := map[string][]string{}
+	var other []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line == "" {
+			continue
+		}
+
+		match := conventionalCommitRe.FindStringSubmatch(line)
+		if match == nil {
+			other = append(other, line)
+			continue
+		}
+
+		title := commitTypeTitle(match[1])
+		if title == "" {
+			other = append(other, line)
+			continue
+		}
+		sections[title] = append(sections[title], line)
+	}
+
+	var sb strings.Builder
+	for _, t := range conventionalCommitTypes {
+		entries, ok := sections[t.Title]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&sb, "## %s\n", t.Title)
+		for _, entry := range entries {
+			sb.WriteString(entry + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	if len(other) > 0 {
+		sb.WriteString("## Other Changes\n")
+		for _, entry := range other {
+			sb.WriteString(entry + "\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+func commitTypeTitle(prefix string) string {
+	for _, t := range conventionalCommitTypes {
+		if t.Prefix == prefix {
+			return t.Title
+		}
+	}
+	return ""
+}
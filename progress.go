@@ -0,0 +1,95 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// progressReader wraps the reader for an asset upload and reports transfer
+// progress for the whole file to stderr as bytes are read from it.
+type progressReader struct {
+	io.Reader
+	name       string
+	total      int64
+	read       int64
+	start      time.Time
+	lastReport time.Time
+}
+
+func newProgressReporter(name string, total int64) *progressReader {
+	return &progressReader{name: name, total: total, start: time.Now()}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.Reader.Read(b)
+	p.read += int64(n)
+	p.report(false)
+	return n, err
+}
+
+// finish prints a final, complete progress line once the whole file has
+// been uploaded.
+func (p *progressReader) finish() {
+	p.report(true)
+}
+
+func (p *progressReader) report(force bool) {
+	now := time.Now()
+	if !force && now.Sub(p.lastReport) < 500*time.Millisecond {
+		return
+	}
+	p.lastReport = now
+
+	elapsed := now.Sub(p.start).Seconds()
+	var throughput float64
+	if elapsed > 0 {
+		throughput = float64(p.read) / elapsed
+	}
+
+	pct := 100
+	if p.total > 0 {
+		pct = int(float64(p.read) / float64(p.total) * 100)
+	}
+
+	if isTerminal(os.Stdout) {
+		eta := "?"
+		if throughput > 0 && p.read < p.total {
+			remaining := time.Duration(float64(p.total-p.read)/throughput) * time.Second
+			eta = remaining.Round(time.Second).String()
+		}
+		fmt.Fprintf(os.Stderr, "\r%s: %d%% %s/s ETA %s   ", p.name, pct, humanBytes(int64(throughput)), eta)
+		if force {
+			fmt.Fprintln(os.Stderr)
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "[%s] %d%% %s/s\n", p.name, pct, humanBytes(int64(throughput)))
+	}
+}
+
+// isTerminal reports whether f looks like an interactive terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (fi.Mode() & os.ModeCharDevice) != 0
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,47 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+)
+
+var showCommand = &Command{
+	Name:  "show",
+	Usage: "show <user/repo> <tag>",
+	Run:   runShow,
+}
+
+func runShow(args []string) error {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("invalid number of arguments (got %d, expected 2: <user/repo> <tag>)", fs.NArg())
+	}
+
+	if err := requireToken(); err != nil {
+		return err
+	}
+
+	if err := setGithubTarget(fs.Arg(0)); err != nil {
+		return err
+	}
+
+	release, err := getReleaseByTag(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	pretty, err := json.MarshalIndent(release, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(pretty))
+	return nil
+}
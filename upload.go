@@ -0,0 +1,94 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// uploadFile uploads a single asset in one request, retrying the whole
+// upload up to -upload-retries times with exponential backoff when the
+// failure looks transient. Progress is reported to stderr as the file
+// streams.
+func uploadFile(uploadURL, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	size, err := fileSize(file)
+	if err != nil {
+		return err
+	}
+
+	filename := filepath.Base(file.Name())
+	log.Printf("Uploading %s...\n", filename)
+
+	algos := checksumAlgorithms()
+	progress := newProgressReporter(filename, size)
+
+	body, err := uploadWithRetry(uploadURL, file, filename, size, algos, progress)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", filename, err)
+	}
+	progress.finish()
+
+	if debug {
+		log.Println("========= UPLOAD RESPONSE ===========")
+		log.Println(string(body[:]))
+	}
+	return nil
+}
+
+// uploadWithRetry POSTs the whole contents of file, retrying up to
+// -upload-retries times with exponential backoff when the failure looks
+// transient. A fresh checksumWriter is used for each attempt so a retry
+// following a failed, partially-read attempt never re-feeds the same bytes
+// into a hash; the digests are only recorded once an attempt succeeds.
+func uploadWithRetry(uploadURL string, file *os.File, filename string, size int64, algos []string, progress *progressReader) ([]byte, error) {
+	var body []byte
+	var lastErr error
+	for attempt := 0; attempt <= uploadRetriesFlag; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+			log.Printf("Retrying upload of %s in %s (attempt %d/%d)...\n", filename, backoff, attempt, uploadRetriesFlag)
+			time.Sleep(backoff)
+		}
+
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		cw := newChecksumWriter(algos)
+		progress.Reader = cw.wrap(file)
+		body, lastErr = doRequest("POST", uploadURL+"?name="+filename, "application/octet-stream", progress, size)
+		if lastErr == nil {
+			cw.record(filename)
+			return body, nil
+		}
+		if !retriable(lastErr) {
+			return nil, lastErr
+		}
+	}
+	return nil, lastErr
+}
+
+// retriable reports whether err is worth retrying: 5xx responses from
+// Github, or a network-level error that never produced a response at all.
+// 4xx responses are treated as terminal.
+func retriable(err error) bool {
+	var httpErr *httpError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+	return true
+}